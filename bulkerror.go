@@ -0,0 +1,83 @@
+package elasticfixtures
+
+import "fmt"
+
+// BulkItemError describes a single document that elasticsearch's _bulk endpoint
+// rejected, identified by its 0-based offset inside the fixture file it came from.
+type BulkItemError struct {
+	Offset int
+	Status int
+	Type   string
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *BulkItemError) Error() string {
+	return fmt.Sprintf("document at offset %d: status %d, type %q, reason %q", e.Offset, e.Status, e.Type, e.Reason)
+}
+
+// BulkError is returned by Load/LoadContext when elasticsearch's _bulk response
+// reports "errors": true for a fixture file. It lists every rejected document so
+// callers get an actionable diagnostic instead of a single opaque error.
+type BulkError struct {
+	File  string
+	Items []*BulkItemError
+}
+
+// Error implements the error interface.
+func (e *BulkError) Error() string {
+	if len(e.Items) == 0 {
+		return fmt.Sprintf("esfixtures: bulk load failed for fixture %q", e.File)
+	}
+
+	return fmt.Sprintf("esfixtures: bulk load failed for fixture %q: %d document(s) rejected, first error: %v",
+		e.File, len(e.Items), e.Items[0])
+}
+
+// Unwrap returns the first document error, so callers can errors.As it into a
+// *BulkItemError (var itemErr *BulkItemError; errors.As(err, &itemErr)).
+func (e *BulkError) Unwrap() error {
+	if len(e.Items) == 0 {
+		return nil
+	}
+
+	return e.Items[0]
+}
+
+// bulkResponse is the subset of the elasticsearch _bulk response body this package cares about.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []struct {
+		Index struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"index"`
+	} `json:"items"`
+}
+
+// toBulkError builds a *BulkError out of a decoded bulk response, or returns nil
+// when the response reports no errors.
+func (r *bulkResponse) toBulkError(fileName string) *BulkError {
+	if !r.Errors {
+		return nil
+	}
+
+	bulkErr := &BulkError{File: fileName}
+	for offset, item := range r.Items {
+		if item.Index.Error.Type == "" {
+			continue
+		}
+
+		bulkErr.Items = append(bulkErr.Items, &BulkItemError{
+			Offset: offset,
+			Status: item.Index.Status,
+			Type:   item.Index.Error.Type,
+			Reason: item.Index.Error.Reason,
+		})
+	}
+
+	return bulkErr
+}
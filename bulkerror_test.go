@@ -0,0 +1,58 @@
+package elasticfixtures
+
+import "testing"
+
+func TestBulkResponse_ToBulkError(t *testing.T) {
+	t.Run("no errors returns nil", func(t *testing.T) {
+		rsp := bulkResponse{Errors: false}
+
+		if err := rsp.toBulkError("orders.json"); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+	})
+
+	t.Run("collects one item per failed document", func(t *testing.T) {
+		var rsp bulkResponse
+		rsp.Errors = true
+		rsp.Items = make([]struct {
+			Index struct {
+				Status int `json:"status"`
+				Error  struct {
+					Type   string `json:"type"`
+					Reason string `json:"reason"`
+				} `json:"error"`
+			} `json:"index"`
+		}, 2)
+		rsp.Items[1].Index.Status = 409
+		rsp.Items[1].Index.Error.Type = "version_conflict_engine_exception"
+		rsp.Items[1].Index.Error.Reason = "already exists"
+
+		bulkErr := rsp.toBulkError("orders.json")
+		if bulkErr == nil {
+			t.Fatal("expected a non-nil BulkError")
+		}
+		if len(bulkErr.Items) != 1 {
+			t.Fatalf("expected 1 item error, got %d", len(bulkErr.Items))
+		}
+		if bulkErr.Items[0].Offset != 1 {
+			t.Errorf("expected offset 1, got %d", bulkErr.Items[0].Offset)
+		}
+	})
+
+	t.Run("errors true with no item error does not panic on Error()", func(t *testing.T) {
+		var rsp bulkResponse
+		rsp.Errors = true
+
+		bulkErr := rsp.toBulkError("orders.json")
+		if bulkErr == nil {
+			t.Fatal("expected a non-nil BulkError")
+		}
+
+		if msg := bulkErr.Error(); msg == "" {
+			t.Error("expected a non-empty error message")
+		}
+		if err := bulkErr.Unwrap(); err != nil {
+			t.Errorf("expected Unwrap to return nil for an empty Items, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package elasticfixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// forEachFixture runs work for every fixture file, honouring WithConcurrency and
+// WithFailFast. With the default concurrency (<=1) it behaves exactly like a
+// sequential for loop, aborting on ctx cancellation or the first error. With a
+// higher concurrency it fans out onto a capped worker pool, sharing l.httpClient
+// so connection reuse actually helps, and returns every failure aggregated via
+// errors.Join instead of just the first one. When WithFailFast is set, the first
+// failure cancels the fixtures still in flight.
+func (l *Loader) forEachFixture(ctx context.Context, work func(ctx context.Context, fixture *fixtureFile) error) error {
+	if l.concurrency <= 1 {
+		for i := range l.fixtureFiles {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := work(ctx, &l.fixtureFiles[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, l.concurrency)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for i := range l.fixtureFiles {
+		fixture := &l.fixtureFiles[i]
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if runCtx.Err() != nil {
+				return
+			}
+
+			if err := work(runCtx, fixture); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("fixture %q: %w", fixture.fileName, err))
+				mu.Unlock()
+
+				if l.failFast {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// every worker may have bailed out on ctx cancellation before running work,
+	// leaving errs empty; surface the cancellation instead of reporting success.
+	if len(errs) == 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package elasticfixtures
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func newLoaderWithFixtures(n int) *Loader {
+	l := &Loader{}
+	l.fixtureFiles = make([]fixtureFile, n)
+	for i := range l.fixtureFiles {
+		l.fixtureFiles[i].fileName = "fixture.json"
+	}
+
+	return l
+}
+
+func TestForEachFixture_Sequential(t *testing.T) {
+	l := newLoaderWithFixtures(3)
+
+	var calls int32
+	err := l.forEachFixture(context.Background(), func(ctx context.Context, fixture *fixtureFile) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected work to run 3 times, got %d", calls)
+	}
+}
+
+func TestForEachFixture_SequentialStopsOnFirstError(t *testing.T) {
+	l := newLoaderWithFixtures(3)
+	boom := errors.New("boom")
+
+	var calls int32
+	err := l.forEachFixture(context.Background(), func(ctx context.Context, fixture *fixtureFile) error {
+		atomic.AddInt32(&calls, 1)
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the sequential loop to stop after the first failure, got %d calls", calls)
+	}
+}
+
+func TestForEachFixture_ConcurrentAggregatesAllErrors(t *testing.T) {
+	l := newLoaderWithFixtures(5)
+	l.concurrency = 5
+	boom := errors.New("boom")
+
+	var calls int32
+	err := l.forEachFixture(context.Background(), func(ctx context.Context, fixture *fixtureFile) error {
+		atomic.AddInt32(&calls, 1)
+		return boom
+	})
+	if err == nil {
+		t.Fatal("expected an aggregated error")
+	}
+	if calls != 5 {
+		t.Errorf("expected every fixture to run even though all fail, got %d calls", calls)
+	}
+}
+
+func TestForEachFixture_ConcurrentPreCancelledContext(t *testing.T) {
+	l := newLoaderWithFixtures(5)
+	l.concurrency = 5
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int32
+	err := l.forEachFixture(ctx, func(ctx context.Context, fixture *fixtureFile) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no work to run against a pre-cancelled context, got %d calls", calls)
+	}
+}
+
+func TestForEachFixture_FailFastCancelsSiblings(t *testing.T) {
+	l := newLoaderWithFixtures(50)
+	l.concurrency = 5
+	l.failFast = true
+	boom := errors.New("boom")
+
+	var calls int32
+	err := l.forEachFixture(context.Background(), func(ctx context.Context, fixture *fixtureFile) error {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			return boom
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls == 50 {
+		t.Error("expected WithFailFast to cancel fixtures still in flight instead of running all of them")
+	}
+}
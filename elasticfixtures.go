@@ -2,20 +2,30 @@ package elasticfixtures
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io/fs"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type fixtureFile struct {
 	path     string
 	fileName string
 	content  []byte
+
+	// mapping and settings hold the raw content of the optional sibling
+	// foo.mapping.json / foo.settings.json files, if present. Either may be nil.
+	mapping  []byte
+	settings []byte
 }
 
+// fileNameWithoutExtension strips whatever extension the fixture file has
+// (.json, .ndjson, .jsonl, .yaml, .yml, ...) to deduct the elasticsearch index name.
 func (f *fixtureFile) fileNameWithoutExtension() (fn string) {
 	fn = strings.Replace(f.fileName, filepath.Ext(f.fileName), "", 1)
 
@@ -25,13 +35,52 @@ func (f *fixtureFile) fileNameWithoutExtension() (fn string) {
 type Loader struct {
 	esUrl        string
 	fixtureFiles []fixtureFile
+	httpClient   *http.Client
+	timeout      time.Duration
+	fsys         fs.FS
+
+	authHeader         string
+	caCertPEM          []byte
+	insecureSkipVerify bool
+
+	recreateIndex bool
+	indexPrefix   string
+
+	concurrency int
+	failFast    bool
 }
 
-// New is creating a new loader and loads all the fixture files provided in fileNames variadic
+// indexNameFor deducts the elasticsearch index name for a fixture file, namespaced
+// by WithIndexPrefix, if set. (ex: for the fixture file example.json, the deducted
+// index name is example, or "<prefix>example" when a prefix is configured)
+func (l *Loader) indexNameFor(f *fixtureFile) string {
+	return l.indexPrefix + f.fileNameWithoutExtension()
+}
+
+// New is creating a new loader and loads all the fixture files provided in fileNames
 // it parses the file contents and store each file content as an element of type []fixtureFile
-func New(serviceUrl string, fileNames ...string) (fixtureLoader *Loader, err error) {
+// fileNames are resolved relative to the current working directory
+// opts can be used to configure the Loader, ex: WithTimeout, WithHTTPClient
+func New(serviceUrl string, fileNames []string, opts ...Option) (fixtureLoader *Loader, err error) {
+	return NewFS(serviceUrl, os.DirFS("."), fileNames, opts...)
+}
+
+// NewFS is the fs.FS-backed variant of New: it loads the fixture files provided in
+// fileNames from fsys instead of the OS filesystem, so an embed.FS (via //go:embed)
+// or any other fs.FS can serve as the fixture source.
+func NewFS(serviceUrl string, fsys fs.FS, fileNames []string, opts ...Option) (fixtureLoader *Loader, err error) {
 	fixtureLoader = new(Loader)
 	fixtureLoader.esUrl = serviceUrl
+	fixtureLoader.httpClient = http.DefaultClient
+	fixtureLoader.fsys = fsys
+
+	for _, opt := range opts {
+		opt(fixtureLoader)
+	}
+	if err = fixtureLoader.configureTransport(); err != nil {
+		return nil, err
+	}
+
 	fixtureLoader.fixtureFiles, err = fixtureLoader.parseFilesContent(fileNames...)
 
 	return
@@ -39,115 +88,177 @@ func New(serviceUrl string, fileNames ...string) (fixtureLoader *Loader, err err
 
 // Clean is deleting all the records from the indexes represented by the fixtures
 // it sends a "delete by query" request for every elasticsearch index from the fixtures
-// the index name is deducted from the fixture file name without extension
+// the index name is deducted from the fixture file name without extension, namespaced
+// by WithIndexPrefix if set
 // (ex: for the fixture file example.json, the deducted index name is example)
 func (l *Loader) Clean() (err error) {
-	cl := http.DefaultClient
-	for i := range l.fixtureFiles {
-		esIndex := l.fixtureFiles[i].fileNameWithoutExtension()
-		reqUrl := fmt.Sprintf("%s/%s/_delete_by_query?conflicts=proceed", l.esUrl, esIndex)
-		reqBody := bytes.NewBufferString(`{
-							"query": {
-							"match_all": {}
-							}
-							}`)
-		delRsp, err := cl.Post(reqUrl, "application/json", reqBody)
-		if err != nil {
-			return err
-		}
+	return l.CleanContext(context.Background())
+}
+
+// CleanContext is the context-aware variant of Clean.
+// Each delete-by-query request gets its own deadline derived from ctx and the
+// Loader's WithTimeout option, if any. A cancelled ctx aborts the remaining
+// fixture files and closes any in-flight request body promptly. With
+// WithConcurrency set above 1, fixture files are cleaned concurrently and every
+// failure is returned aggregated, instead of just the first one.
+func (l *Loader) CleanContext(ctx context.Context) (err error) {
+	return l.forEachFixture(ctx, l.cleanFixtureFile)
+}
+
+func (l *Loader) cleanFixtureFile(ctx context.Context, fixture *fixtureFile) error {
+	opCtx, cancel := l.withOperationDeadline(ctx)
+	defer cancel()
 
-		_ = delRsp.Body.Close()
+	esIndex := l.indexNameFor(fixture)
+	reqUrl := fmt.Sprintf("%s/%s/_delete_by_query?conflicts=proceed", l.esUrl, esIndex)
+	reqBody := bytes.NewBufferString(`{
+						"query": {
+						"match_all": {}
+						}
+						}`)
+
+	req, err := http.NewRequestWithContext(opCtx, http.MethodPost, reqUrl, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	delRsp, err := l.httpClient.Do(req)
+	if err != nil {
+		return err
 	}
+	_ = delRsp.Body.Close()
 
 	return nil
 }
 
 // Load is saving the fixture data to elasticsearch
 // it sends a request for every elasticsearch index
-// the index name is deducted from the fixture file name without extension
+// the index name is deducted from the fixture file name without extension, namespaced
+// by WithIndexPrefix if set
 // (ex: for the fixture file example.json, the deducted index name is example)
+// when the fixture has a sibling foo.mapping.json and/or foo.settings.json, the index
+// is provisioned with them via `PUT /{index}` before the bulk load
 func (l *Loader) Load() (err error) {
-	cl := http.DefaultClient
+	return l.LoadContext(context.Background())
+}
 
-	for i := range l.fixtureFiles {
-		JSONObjects, err := splitJSONIntoJSONCollection(l.fixtureFiles[i].content)
-		if err != nil {
-			return fmt.Errorf("esfixtures: invalid data provided for fixture: %v, err: %w", l.fixtureFiles[i].fileName, err)
-		}
+// LoadContext is the context-aware variant of Load.
+// Each request made for a fixture file (index delete, index provisioning, bulk)
+// gets its own deadline derived from ctx and the Loader's WithTimeout option, if any. A cancelled ctx aborts the outer file loop and
+// closes any in-flight request body promptly. With WithConcurrency set above 1,
+// fixture files are loaded concurrently and every failure is returned
+// aggregated, instead of just the first one.
+func (l *Loader) LoadContext(ctx context.Context) (err error) {
+	return l.forEachFixture(ctx, l.loadFixtureFile)
+}
 
-		var reqBodyPayloadBulk, reqBodyPayloadSingle []byte
-		for j := range JSONObjects {
-			reqBodyPayloadBulk = append(reqBodyPayloadBulk, []byte("{\"index\": {}}\n")...)
-			reqBodyPayloadBulk = append(reqBodyPayloadBulk, JSONObjects[j]...)
-			reqBodyPayloadBulk = append(reqBodyPayloadBulk, []byte("\n")...)
-		}
+func (l *Loader) loadFixtureFile(ctx context.Context, fixture *fixtureFile) (err error) {
+	JSONObjects, err := parseFixtureDocuments(fixture.fileName, fixture.content)
+	if err != nil {
+		return fmt.Errorf("esfixtures: invalid data provided for fixture: %v, err: %w", fixture.fileName, err)
+	}
 
-		esIndex := l.fixtureFiles[i].fileNameWithoutExtension()
-		reqUrl := fmt.Sprintf("%s/%s/_bulk?refresh=true", l.esUrl, esIndex)
-		reqBodyBulk := bytes.NewBuffer(reqBodyPayloadBulk)
-		loadRspBulk, err := cl.Post(reqUrl, "application/x-ndjson", reqBodyBulk)
-		if err != nil {
-			return err
-		}
-		_ = loadRspBulk.Body.Close()
-
-		// temporary try to fix ES5 _bulk issue
-		// todo: find a better way of doing this
-		if loadRspBulk.StatusCode != http.StatusCreated && loadRspBulk.StatusCode != http.StatusOK {
-			for k := range JSONObjects {
-				reqBodyPayloadSingle = JSONObjects[k]
-				esType := strings.Split(esIndex, "_index")
-				innerIndex := esType[0]
-				var loadRspSingle *http.Response
-				// this is based on the fact that the index has an inner type with the same name as the index, but without "_index"
-				if len(innerIndex) != 0 {
-					reqUrl = fmt.Sprintf("%s/%s/%s?refresh=true", l.esUrl, esIndex, innerIndex)
-					reqBodySingle := bytes.NewBuffer(reqBodyPayloadSingle)
-					loadRspSingle, err = cl.Post(reqUrl, "application/json", reqBodySingle)
-					if err != nil {
-						return err
-					}
-				} else {
-					// try to index without inner type
-					reqUrl = fmt.Sprintf("%s/%s?refresh=true", l.esUrl, esIndex)
-					reqBodySingle := bytes.NewBuffer(reqBodyPayloadSingle)
-					loadRspSingle, err = cl.Post(reqUrl, "application/json", reqBodySingle)
-					if err != nil {
-						return err
-					}
-				}
-				if loadRspSingle.StatusCode != http.StatusCreated && loadRspSingle.StatusCode != http.StatusOK {
-					err = fmt.Errorf("can't load fixture for file: %v, err: %v", l.fixtureFiles[i].fileName, loadRspSingle.Body)
-				}
-				_ = loadRspSingle.Body.Close()
-			}
-		}
-		if err != nil {
-			return err
-		}
+	var reqBodyPayloadBulk []byte
+	for j := range JSONObjects {
+		reqBodyPayloadBulk = append(reqBodyPayloadBulk, []byte("{\"index\": {}}\n")...)
+		reqBodyPayloadBulk = append(reqBodyPayloadBulk, JSONObjects[j]...)
+		reqBodyPayloadBulk = append(reqBodyPayloadBulk, []byte("\n")...)
 	}
 
-	return err
+	esIndex := l.indexNameFor(fixture)
+	if err = l.provisionIndex(ctx, esIndex, fixture); err != nil {
+		return err
+	}
+
+	opCtx, cancel := l.withOperationDeadline(ctx)
+	defer cancel()
+
+	reqUrl := fmt.Sprintf("%s/%s/_bulk?refresh=true", l.esUrl, esIndex)
+	reqBodyBulk := bytes.NewBuffer(reqBodyPayloadBulk)
+
+	req, err := http.NewRequestWithContext(opCtx, http.MethodPost, reqUrl, reqBodyBulk)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	loadRspBulk, err := l.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = loadRspBulk.Body.Close() }()
+
+	if loadRspBulk.StatusCode != http.StatusCreated && loadRspBulk.StatusCode != http.StatusOK {
+		return fmt.Errorf("esfixtures: bulk request for fixture %q failed with status %d", fixture.fileName, loadRspBulk.StatusCode)
+	}
+
+	var bulkRsp bulkResponse
+	if err = json.NewDecoder(loadRspBulk.Body).Decode(&bulkRsp); err != nil {
+		return fmt.Errorf("esfixtures: could not decode bulk response for fixture %q: %w", fixture.fileName, err)
+	}
+
+	if bulkErr := bulkRsp.toBulkError(fixture.fileName); bulkErr != nil {
+		return bulkErr
+	}
+
+	return nil
+}
+
+// withOperationDeadline derives a context for a single outgoing request, applying
+// the Loader's configured WithTimeout, if any, on top of ctx.
+func (l *Loader) withOperationDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, l.timeout)
 }
 
 // parseFilesContent parse the content of the file(s) provided in fileNames variadic
+// files are read from l.fsys, which defaults to the OS filesystem for Loaders built with New
+// alongside foo.json, an optional foo.mapping.json and/or foo.settings.json are picked up too
 func (l *Loader) parseFilesContent(fileNames ...string) (parsedContent []fixtureFile, err error) {
 	for _, f := range fileNames {
 		fixture := fixtureFile{
 			path:     f,
 			fileName: filepath.Base(f),
 		}
-		fixture.content, err = ioutil.ReadFile(fixture.path)
+		fixture.content, err = fs.ReadFile(l.fsys, fixture.path)
 		if err != nil {
 			err = fmt.Errorf(`esfixtures: could not read file "%s": %w`, fixture.path, err)
 			return
 		}
+
+		base := strings.TrimSuffix(fixture.path, filepath.Ext(fixture.path))
+		if fixture.mapping, err = l.readOptionalSibling(base + ".mapping.json"); err != nil {
+			return
+		}
+		if fixture.settings, err = l.readOptionalSibling(base + ".settings.json"); err != nil {
+			return
+		}
+
 		parsedContent = append(parsedContent, fixture)
 	}
 
 	return
 }
 
+// readOptionalSibling reads path from l.fsys, returning a nil slice (and no error)
+// when the file does not exist.
+func (l *Loader) readOptionalSibling(path string) ([]byte, error) {
+	content, err := fs.ReadFile(l.fsys, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf(`esfixtures: could not read file "%s": %w`, path, err)
+	}
+
+	return content, nil
+}
+
 // splitJSONIntoJSONCollection receive a JSON representing an object collection([{object11}, {object2}, ...]) or a single object({object}) in []byte format
 // and returns a slice with all the JSON objects, each object represented as []byte
 func splitJSONIntoJSONCollection(jc []byte) (jsonCollection [][]byte, err error) {
@@ -0,0 +1,65 @@
+package elasticfixtures
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"orders.json":             {Data: []byte(`[{"id": 1}]`)},
+		"orders.mapping.json":     {Data: []byte(`{"properties": {"id": {"type": "keyword"}}}`)},
+		"customers.json":          {Data: []byte(`{"id": 2}`)},
+		"customers.settings.json": {Data: []byte(`{"number_of_shards": 1}`)},
+	}
+
+	loader, err := NewFS("http://localhost:9200", fsys, []string{"orders.json", "customers.json"})
+	if err != nil {
+		t.Fatalf("NewFS returned an unexpected error: %v", err)
+	}
+
+	if len(loader.fixtureFiles) != 2 {
+		t.Fatalf("expected 2 fixture files, got %d", len(loader.fixtureFiles))
+	}
+
+	orders := loader.fixtureFiles[0]
+	if orders.fileNameWithoutExtension() != "orders" {
+		t.Errorf("expected index name %q, got %q", "orders", orders.fileNameWithoutExtension())
+	}
+	if string(orders.mapping) != `{"properties": {"id": {"type": "keyword"}}}` {
+		t.Errorf("expected orders.mapping.json content to be picked up, got %q", orders.mapping)
+	}
+	if orders.settings != nil {
+		t.Errorf("expected orders to have no settings sibling, got %q", orders.settings)
+	}
+
+	customers := loader.fixtureFiles[1]
+	if string(customers.settings) != `{"number_of_shards": 1}` {
+		t.Errorf("expected customers.settings.json content to be picked up, got %q", customers.settings)
+	}
+	if customers.mapping != nil {
+		t.Errorf("expected customers to have no mapping sibling, got %q", customers.mapping)
+	}
+}
+
+func TestNewFS_MissingFile(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	if _, err := NewFS("http://localhost:9200", fsys, []string{"missing.json"}); err == nil {
+		t.Fatal("expected an error for a fixture file that does not exist, got nil")
+	}
+}
+
+func TestIndexNameFor_WithPrefix(t *testing.T) {
+	loader, err := NewFS("http://localhost:9200", fstest.MapFS{
+		"orders.json": {Data: []byte(`[]`)},
+	}, []string{"orders.json"}, WithIndexPrefix("test-"))
+	if err != nil {
+		t.Fatalf("NewFS returned an unexpected error: %v", err)
+	}
+
+	got := loader.indexNameFor(&loader.fixtureFiles[0])
+	if got != "test-orders" {
+		t.Errorf("expected prefixed index name %q, got %q", "test-orders", got)
+	}
+}
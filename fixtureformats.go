@@ -0,0 +1,71 @@
+package elasticfixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseFixtureDocuments extracts the individual documents out of a fixture file's
+// raw content, dispatching on fileName's extension: .json keeps the existing
+// single-object/array behavior, .ndjson/.jsonl splits on newlines (the same
+// shape elasticsearch itself uses for _bulk), and .yaml/.yml decodes a
+// top-level sequence or mapping, re-marshalled to JSON per document.
+func parseFixtureDocuments(fileName string, content []byte) ([][]byte, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".ndjson", ".jsonl":
+		return splitNDJSONIntoJSONCollection(content)
+	case ".yaml", ".yml":
+		return splitYAMLIntoJSONCollection(content)
+	default:
+		return splitJSONIntoJSONCollection(content)
+	}
+}
+
+// splitNDJSONIntoJSONCollection treats every non-blank line of content as one JSON document.
+func splitNDJSONIntoJSONCollection(content []byte) (jsonCollection [][]byte, err error) {
+	lines := bytes.Split(content, []byte("\n"))
+	for _, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		if !json.Valid(line) {
+			return nil, fmt.Errorf("invalid json line provided: %v", string(line))
+		}
+
+		jsonCollection = append(jsonCollection, line)
+	}
+
+	return jsonCollection, nil
+}
+
+// splitYAMLIntoJSONCollection decodes a top-level YAML sequence or mapping and
+// re-marshals each element to JSON, handing back the same [][]byte shape as
+// splitJSONIntoJSONCollection.
+func splitYAMLIntoJSONCollection(content []byte) (jsonCollection [][]byte, err error) {
+	var root interface{}
+	if err = yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("invalid yaml provided: %w", err)
+	}
+
+	sequence, isSequence := root.([]interface{})
+	if !isSequence {
+		sequence = []interface{}{root}
+	}
+
+	for _, doc := range sequence {
+		obj, marshalErr := json.Marshal(doc)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		jsonCollection = append(jsonCollection, obj)
+	}
+
+	return jsonCollection, nil
+}
@@ -0,0 +1,70 @@
+package elasticfixtures
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseFixtureDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		want     int
+	}{
+		{"json array", "orders.json", `[{"id": 1}, {"id": 2}]`, 2},
+		{"json object", "orders.json", `{"id": 1}`, 1},
+		{"ndjson", "orders.ndjson", "{\"id\": 1}\n{\"id\": 2}\n", 2},
+		{"jsonl skips blank lines", "orders.jsonl", "{\"id\": 1}\n\n{\"id\": 2}\n", 2},
+		{"yaml sequence", "orders.yaml", "- id: 1\n- id: 2\n", 2},
+		{"yml mapping", "orders.yml", "id: 1\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := parseFixtureDocuments(tt.fileName, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(docs) != tt.want {
+				t.Fatalf("expected %d document(s), got %d", tt.want, len(docs))
+			}
+			for _, doc := range docs {
+				if !json.Valid(doc) {
+					t.Errorf("expected document to be valid json, got %q", doc)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitNDJSONIntoJSONCollection_InvalidLine(t *testing.T) {
+	_, err := splitNDJSONIntoJSONCollection([]byte("not json"))
+	if err == nil {
+		t.Fatal("expected an error for an invalid json line")
+	}
+}
+
+func TestSplitYAMLIntoJSONCollection_InvalidYAML(t *testing.T) {
+	_, err := splitYAMLIntoJSONCollection([]byte("- [unterminated"))
+	if err == nil {
+		t.Fatal("expected an error for invalid yaml")
+	}
+}
+
+func TestFileNameWithoutExtension(t *testing.T) {
+	tests := map[string]string{
+		"orders.json":   "orders",
+		"orders.ndjson": "orders",
+		"orders.jsonl":  "orders",
+		"orders.yaml":   "orders",
+		"orders.yml":    "orders",
+	}
+
+	for fileName, want := range tests {
+		f := fixtureFile{fileName: fileName}
+		if got := f.fileNameWithoutExtension(); got != want {
+			t.Errorf("fileNameWithoutExtension(%q) = %q, want %q", fileName, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package elasticfixtures
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// indexConfigPayload builds the body for a `PUT /{index}` request out of a
+// fixture's optional sibling mapping/settings files. It returns nil when
+// neither is present, meaning the index should be left to dynamic mapping.
+func (f *fixtureFile) indexConfigPayload() ([]byte, error) {
+	if f.mapping == nil && f.settings == nil {
+		return nil, nil
+	}
+
+	payload := make(map[string]json.RawMessage, 2)
+	if f.settings != nil {
+		payload["settings"] = f.settings
+	}
+	if f.mapping != nil {
+		payload["mappings"] = f.mapping
+	}
+
+	return json.Marshal(payload)
+}
+
+// provisionIndex prepares the elasticsearch index for esIndex ahead of a bulk load:
+// it recreates the index when WithRecreateIndex is set, and issues `PUT /{index}`
+// with the fixture's sibling mapping/settings files, if either is present.
+func (l *Loader) provisionIndex(ctx context.Context, esIndex string, fixture *fixtureFile) error {
+	configPayload, err := fixture.indexConfigPayload()
+	if err != nil {
+		return fmt.Errorf("esfixtures: invalid mapping/settings for fixture %q: %w", fixture.fileName, err)
+	}
+
+	if l.recreateIndex {
+		if err = l.deleteIndex(ctx, esIndex); err != nil {
+			return err
+		}
+	}
+
+	if configPayload == nil {
+		return nil
+	}
+
+	return l.putIndexConfig(ctx, esIndex, configPayload)
+}
+
+func (l *Loader) deleteIndex(ctx context.Context, esIndex string) error {
+	opCtx, cancel := l.withOperationDeadline(ctx)
+	defer cancel()
+
+	reqUrl := fmt.Sprintf("%s/%s", l.esUrl, esIndex)
+	req, err := http.NewRequestWithContext(opCtx, http.MethodDelete, reqUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	rsp, err := l.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rsp.Body.Close() }()
+
+	// a missing index is not an error: that's the state we want before provisioning
+	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("esfixtures: could not delete index %q: status %d", esIndex, rsp.StatusCode)
+	}
+
+	return nil
+}
+
+func (l *Loader) putIndexConfig(ctx context.Context, esIndex string, payload []byte) error {
+	opCtx, cancel := l.withOperationDeadline(ctx)
+	defer cancel()
+
+	reqUrl := fmt.Sprintf("%s/%s", l.esUrl, esIndex)
+	req, err := http.NewRequestWithContext(opCtx, http.MethodPut, reqUrl, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := l.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rsp.Body.Close() }()
+
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("esfixtures: could not provision index %q: status %d", esIndex, rsp.StatusCode)
+	}
+
+	return nil
+}
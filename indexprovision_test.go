@@ -0,0 +1,60 @@
+package elasticfixtures
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFixtureFile_IndexConfigPayload(t *testing.T) {
+	t.Run("neither mapping nor settings returns nil", func(t *testing.T) {
+		f := fixtureFile{}
+
+		payload, err := f.indexConfigPayload()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if payload != nil {
+			t.Errorf("expected nil payload, got %q", payload)
+		}
+	})
+
+	t.Run("combines mapping and settings", func(t *testing.T) {
+		f := fixtureFile{
+			mapping:  []byte(`{"properties": {"id": {"type": "keyword"}}}`),
+			settings: []byte(`{"number_of_shards": 1}`),
+		}
+
+		payload, err := f.indexConfigPayload()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var decoded map[string]json.RawMessage
+		if err = json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("payload is not valid json: %v", err)
+		}
+		if _, ok := decoded["mappings"]; !ok {
+			t.Error("expected payload to have a \"mappings\" key")
+		}
+		if _, ok := decoded["settings"]; !ok {
+			t.Error("expected payload to have a \"settings\" key")
+		}
+	})
+
+	t.Run("settings only omits mappings key", func(t *testing.T) {
+		f := fixtureFile{settings: []byte(`{"number_of_shards": 1}`)}
+
+		payload, err := f.indexConfigPayload()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var decoded map[string]json.RawMessage
+		if err = json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("payload is not valid json: %v", err)
+		}
+		if _, ok := decoded["mappings"]; ok {
+			t.Error("expected payload to not have a \"mappings\" key")
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package elasticfixtures
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Option configures a Loader at construction time.
+type Option func(*Loader)
+
+// WithTimeout sets a deadline applied to every outgoing request the Loader makes
+// (each fixture file load, delete-by-query, etc. gets its own fresh deadline).
+// It is typically derived from a test's t.Deadline(). A zero duration (the
+// default) means no additional deadline is applied beyond the caller's context.
+func WithTimeout(d time.Duration) Option {
+	return func(l *Loader) {
+		l.timeout = d
+	}
+}
+
+// WithHTTPClient overrides the *http.Client used by the Loader, allowing callers
+// to reuse a client with connection pooling, custom transports, etc.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(l *Loader) {
+		l.httpClient = hc
+	}
+}
+
+// WithBasicAuth configures the Loader to send an HTTP Basic Authorization header,
+// derived from user/pass, on every request it issues (Load, Clean, and their
+// context-aware variants alike).
+func WithBasicAuth(user, pass string) Option {
+	return func(l *Loader) {
+		creds := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", user, pass)))
+		l.authHeader = "Basic " + creds
+	}
+}
+
+// WithAPIKey configures the Loader to send an Elasticsearch API key Authorization
+// header on every request it issues. encoded is the base64-encoded "id:api_key"
+// value elasticsearch expects, as returned by the _security/api_key API.
+func WithAPIKey(encoded string) Option {
+	return func(l *Loader) {
+		l.authHeader = "ApiKey " + encoded
+	}
+}
+
+// WithCACert configures the Loader to trust the given PEM-encoded CA certificate
+// when dialing the cluster over TLS, in addition to the system trust store.
+func WithCACert(pem []byte) Option {
+	return func(l *Loader) {
+		l.caCertPEM = pem
+	}
+}
+
+// WithInsecureSkipVerify disables TLS certificate verification. It exists for
+// talking to clusters with self-signed certificates in local/dev setups and
+// should not be used against production clusters.
+func WithInsecureSkipVerify(skip bool) Option {
+	return func(l *Loader) {
+		l.insecureSkipVerify = skip
+	}
+}
+
+// WithRecreateIndex makes Load delete the index, if it exists, before
+// provisioning and bulk-indexing it, guaranteeing a clean mapping/settings
+// state instead of layering onto whatever the index already had.
+func WithRecreateIndex(recreate bool) Option {
+	return func(l *Loader) {
+		l.recreateIndex = recreate
+	}
+}
+
+// WithIndexPrefix namespaces every deducted index name with prefix, so that
+// parallel test runs (ex: go test -p N) against one shared cluster don't
+// collide on the same index. Clean scopes its delete-by-query to the same
+// prefixed index.
+func WithIndexPrefix(prefix string) Option {
+	return func(l *Loader) {
+		l.indexPrefix = prefix
+	}
+}
+
+// WithConcurrency runs the per-fixture-file work of Load and Clean on a worker
+// pool capped at n, instead of strictly sequentially. n <= 1 (the default)
+// keeps the original sequential behavior.
+func WithConcurrency(n int) Option {
+	return func(l *Loader) {
+		l.concurrency = n
+	}
+}
+
+// WithFailFast cancels the fixture files still in flight as soon as one of them
+// fails, when used together with WithConcurrency. It has no effect at the
+// default concurrency, where the first error already aborts the remaining files.
+func WithFailFast(failFast bool) Option {
+	return func(l *Loader) {
+		l.failFast = failFast
+	}
+}
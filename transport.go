@@ -0,0 +1,85 @@
+package elasticfixtures
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+)
+
+// authRoundTripper injects a static Authorization header into every outgoing
+// request before delegating to next, so a single configured Loader can talk to
+// clusters that require basic auth or an API key, for both Load and Clean.
+type authRoundTripper struct {
+	value string
+	next  http.RoundTripper
+}
+
+func (t *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.value != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", t.value)
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// configureTransport wraps l.httpClient's transport with TLS and/or
+// authentication settings collected from WithBasicAuth, WithAPIKey,
+// WithCACert and WithInsecureSkipVerify. It is a no-op when none of those
+// options were used, leaving the caller-provided (or default) client untouched.
+// Only an *http.Transport is cloned and wrapped; any other (or a nil) transport
+// is replaced by a clone of http.DefaultTransport.
+func (l *Loader) configureTransport() error {
+	if l.authHeader == "" && l.caCertPEM == nil && !l.insecureSkipVerify {
+		return nil
+	}
+
+	clientCopy := *l.httpClient
+
+	base, ok := clientCopy.Transport.(*http.Transport)
+	if !ok || base == nil {
+		base = http.DefaultTransport.(*http.Transport).Clone()
+	} else {
+		base = base.Clone()
+	}
+
+	if l.caCertPEM != nil || l.insecureSkipVerify {
+		tlsConfig, err := l.buildTLSConfig(base.TLSClientConfig)
+		if err != nil {
+			return err
+		}
+		base.TLSClientConfig = tlsConfig
+	}
+
+	clientCopy.Transport = &authRoundTripper{value: l.authHeader, next: base}
+	l.httpClient = &clientCopy
+
+	return nil
+}
+
+// buildTLSConfig derives a tls.Config for the Loader's CA cert / insecure-skip-verify
+// options, cloning existing rather than mutating it in place.
+func (l *Loader) buildTLSConfig(existing *tls.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if existing != nil {
+		tlsConfig = existing.Clone()
+	}
+
+	if l.caCertPEM != nil {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(l.caCertPEM) {
+			return nil, fmt.Errorf("esfixtures: WithCACert: no certificates found in PEM input")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if l.insecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,108 @@
+package elasticfixtures
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestAuthRoundTripper_InjectsAuthorizationHeader(t *testing.T) {
+	var gotHeader string
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotHeader = req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	rt := &authRoundTripper{value: "Basic dXNlcjpwYXNz", next: next}
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:9200", nil)
+
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotHeader != "Basic dXNlcjpwYXNz" {
+		t.Errorf("expected Authorization header to be injected, got %q", gotHeader)
+	}
+}
+
+func TestAuthRoundTripper_NoHeaderWhenValueEmpty(t *testing.T) {
+	var gotHeader string
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotHeader = req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	rt := &authRoundTripper{next: next}
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:9200", nil)
+
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotHeader != "" {
+		t.Errorf("expected no Authorization header, got %q", gotHeader)
+	}
+}
+
+func TestLoader_ConfigureTransport(t *testing.T) {
+	t.Run("no-op when no transport option was used", func(t *testing.T) {
+		l := &Loader{httpClient: http.DefaultClient}
+
+		if err := l.configureTransport(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if l.httpClient != http.DefaultClient {
+			t.Error("expected httpClient to be left untouched")
+		}
+	})
+
+	t.Run("wraps the transport to inject the auth header", func(t *testing.T) {
+		l := &Loader{httpClient: http.DefaultClient, authHeader: "ApiKey abc"}
+
+		if err := l.configureTransport(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		rt, ok := l.httpClient.Transport.(*authRoundTripper)
+		if !ok {
+			t.Fatalf("expected an *authRoundTripper, got %T", l.httpClient.Transport)
+		}
+		if rt.value != "ApiKey abc" {
+			t.Errorf("expected the configured auth header, got %q", rt.value)
+		}
+	})
+
+	t.Run("invalid CA cert PEM surfaces an error instead of silently succeeding", func(t *testing.T) {
+		l := &Loader{httpClient: http.DefaultClient, caCertPEM: []byte("not a pem")}
+
+		if err := l.configureTransport(); err == nil {
+			t.Fatal("expected an error for a malformed CA cert")
+		}
+	})
+}
+
+func TestLoader_BuildTLSConfig(t *testing.T) {
+	t.Run("invalid PEM returns an error", func(t *testing.T) {
+		l := &Loader{caCertPEM: []byte("not a pem")}
+
+		if _, err := l.buildTLSConfig(nil); err == nil {
+			t.Fatal("expected an error for a malformed CA cert")
+		}
+	})
+
+	t.Run("insecure skip verify is carried over", func(t *testing.T) {
+		l := &Loader{insecureSkipVerify: true}
+
+		tlsConfig, err := l.buildTLSConfig(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !tlsConfig.InsecureSkipVerify {
+			t.Error("expected InsecureSkipVerify to be true")
+		}
+	})
+}